@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// SetupRootCommand sets default usage, help, and error handling for the
+// (root) command.
+func SetupRootCommand(rootCmd *cobra.Command) {
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	rootCmd.CompletionOptions.HiddenDefaultCmd = true
+	// Descriptions are enabled so that shells which support them (bash, zsh,
+	// fish) can show a short explanation alongside each completion candidate,
+	// as produced by completion.FromListWithDesc.
+	rootCmd.CompletionOptions.DisableDescriptions = false
+}
+
+// BashCompletionSetup is a line of bash that turns on case-insensitive
+// completion matching so that, alongside the lowercase capability synonyms
+// added to linuxCapabilitiesWithDesc, a capability typed in any case, with
+// or without the "CAP_" prefix, matches a candidate. It is not part of the
+// script that `docker completion bash` generates.
+const BashCompletionSetup = `bind "set completion-ignore-case on" 2>/dev/null || true`
+
+// ZshCompletionSetup is the zsh counterpart of BashCompletionSetup. It is
+// not part of the script that `docker completion zsh` generates.
+const ZshCompletionSetup = `zstyle ':completion:*' matcher-list 'm:{a-zA-Z}={A-Za-z}'`
@@ -0,0 +1,44 @@
+package completion
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/spf13/cobra"
+)
+
+// ContainerNamesFiltered is a variant of ContainerNames that only offers
+// containers for which match returns true, e.g. to restrict completion of
+// `--volumes-from` to containers that actually have mounts. showAll mirrors
+// the "all" parameter of ContainerNames: when false, only running containers
+// are considered. A nil match offers every container, equivalent to
+// ContainerNames.
+func ContainerNamesFiltered(cli APIClientProvider, showAll bool, match func(container.Summary) bool) ValidArgsFn {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
+		defer cancel()
+
+		list, err := cli.Client().ContainerList(ctx, container.ListOptions{All: showAll})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		for _, ctr := range list {
+			if match != nil && !match(ctr) {
+				continue
+			}
+			for _, n := range ctr.Names {
+				names = append(names, strings.TrimPrefix(n, "/"))
+			}
+			id := ctr.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			names = append(names, id)
+		}
+		return FromList(names...)(cmd, args, toComplete)
+	}
+}
@@ -0,0 +1,56 @@
+package completion
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// FilterKeys returns a ValidArgsFn that completes the keys of a `--filter
+// key=value` flag: each key is offered followed by `=`, with
+// ShellCompDirectiveNoSpace so that the value can be typed right away. It's
+// usually not registered directly; use FilterValues instead, which uses this
+// for the value being completed as long as it contains no `=`.
+func FilterKeys(keys []string) ValidArgsFn {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		out := make([]string, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, k+"=")
+		}
+		sort.Strings(out)
+		return out, cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// FilterValues returns a ValidArgsFn suitable for registering on a `--filter`
+// flag. It splits the value being completed on the first `=`: with no `=`
+// yet, it offers the keys in values (see FilterKeys); once a known key is
+// followed by `=`, completion is dispatched to that key's ValidArgsFn for the
+// remainder. Only the keys in values are offered; a key whose completer is
+// nil is offered but doesn't complete any value, and a key that isn't in
+// values completes nothing.
+func FilterValues(values map[string]ValidArgsFn) ValidArgsFn {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	completeKeys := FilterKeys(keys)
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		key, value, hasEq := strings.Cut(toComplete, "=")
+		if !hasEq {
+			return completeKeys(cmd, args, toComplete)
+		}
+		complete, ok := values[key]
+		if !ok || complete == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, directive := complete(cmd, args, value)
+		out := make([]string, 0, len(names))
+		for _, n := range names {
+			out = append(out, key+"="+n)
+		}
+		return out, directive
+	}
+}
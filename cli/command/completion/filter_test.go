@@ -0,0 +1,49 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+// statusValues is a fixed-order stand-in for a real value completer, so
+// these tests don't depend on the ordering behavior of completion.FromList.
+func statusValues(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"running", "exited"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func TestFilterValuesOffersKeysWithoutEquals(t *testing.T) {
+	fn := FilterValues(map[string]ValidArgsFn{
+		"status": statusValues,
+		"label":  NoComplete,
+	})
+	values, directive := fn(&cobra.Command{}, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, []string{"label=", "status="})
+}
+
+func TestFilterValuesDispatchesOnKey(t *testing.T) {
+	fn := FilterValues(map[string]ValidArgsFn{
+		"status": statusValues,
+	})
+	values, directive := fn(&cobra.Command{}, []string{}, "status=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.DeepEqual(t, values, []string{"status=running", "status=exited"})
+}
+
+func TestFilterValuesUnknownKey(t *testing.T) {
+	fn := FilterValues(map[string]ValidArgsFn{
+		"status": statusValues,
+	})
+	values, directive := fn(&cobra.Command{}, []string{}, "bogus=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.Check(t, len(values) == 0)
+}
+
+func TestFilterKeys(t *testing.T) {
+	fn := FilterKeys([]string{"b", "a"})
+	values, directive := fn(&cobra.Command{}, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, []string{"a=", "b="})
+}
@@ -0,0 +1,29 @@
+package completion
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// FromListWithDesc is like FromList, but takes a map of value to description,
+// and returns completions in cobra's "value\tdescription" format so that
+// shells with description support (bash, zsh, fish) can show them alongside
+// the value. Descriptions are only rendered when completion descriptions are
+// enabled (see cobra.CompletionOptions.DisableDescriptions in cli/cobra.go);
+// shells without support for them, or with them disabled, ignore the part
+// after the tab.
+func FromListWithDesc(values map[string]string) ValidArgsFn {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		out := make([]string, 0, len(values))
+		for v, desc := range values {
+			if desc == "" {
+				out = append(out, v)
+				continue
+			}
+			out = append(out, v+"\t"+desc)
+		}
+		sort.Strings(out)
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
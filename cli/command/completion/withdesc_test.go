@@ -0,0 +1,24 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestFromListWithDesc(t *testing.T) {
+	fn := FromListWithDesc(map[string]string{
+		"always": "Always restart",
+		"no":     "Do not automatically restart",
+	})
+	values, directive := fn(&cobra.Command{}, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.DeepEqual(t, values, []string{"always\tAlways restart", "no\tDo not automatically restart"})
+}
+
+func TestFromListWithDescNoDescription(t *testing.T) {
+	fn := FromListWithDesc(map[string]string{"all": ""})
+	values, _ := fn(&cobra.Command{}, []string{}, "")
+	assert.DeepEqual(t, values, []string{"all"})
+}
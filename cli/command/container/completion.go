@@ -1,8 +1,12 @@
 package container
 
 import (
+	"context"
+	"os/exec"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/completion"
@@ -15,57 +19,178 @@ import (
 // allCaps is the magic value for "all capabilities".
 const allCaps = "ALL"
 
-// allLinuxCapabilities is a list of all known Linux capabilities.
-//
-// TODO(thaJeztah): add descriptions, and enable descriptions for our completion scripts (cobra.CompletionOptions.DisableDescriptions is currently set to "true")
-// TODO(thaJeztah): consider what casing we want to use for completion (see below);
-//
-// We need to consider what format is most convenient; currently we use the
-// canonical name (uppercase and "CAP_" prefix), however, tab-completion is
-// case-sensitive by default, so requires the user to type uppercase letters
-// to filter the list of options.
-//
-// Bash completion provides a `completion-ignore-case on` option to make completion
-// case-insensitive (https://askubuntu.com/a/87066), but it looks to be a global
-// option; the current cobra.CompletionOptions also don't provide this as an option
-// to be used in the generated completion-script.
-//
-// Fish completion has `smartcase` (by default?) which matches any case if
-// all of the input is lowercase.
+// linuxCapabilityDescriptions gives a short, human-readable summary for each
+// known Linux capability, keyed by its lower-case name (as returned by
+// capability.Cap.String()). Capabilities with no entry here are still
+// completed, just without a description.
+var linuxCapabilityDescriptions = map[string]string{
+	"chown":              "Make arbitrary changes to file UIDs and GIDs",
+	"dac_override":       "Bypass file read, write, and execute permission checks",
+	"dac_read_search":    "Bypass file read permission and directory read/execute checks",
+	"fowner":             "Bypass permission checks on operations that require file ownership",
+	"fsetid":             "Don't clear set-user/group-ID bits when a file is modified",
+	"kill":               "Bypass permission checks for sending signals",
+	"setgid":             "Make arbitrary manipulations of process GIDs",
+	"setuid":             "Make arbitrary manipulations of process UIDs",
+	"setpcap":            "Add or remove capabilities from another process",
+	"linux_immutable":    "Set the FS_APPEND_FL and FS_IMMUTABLE_FL inode flags",
+	"net_bind_service":   "Bind a socket to privileged ports (port numbers less than 1024)",
+	"net_broadcast":      "Allow broadcasting and listening to multicast",
+	"net_admin":          "Perform various network-related operations",
+	"net_raw":            "Use RAW and PACKET sockets",
+	"ipc_lock":           "Lock memory",
+	"ipc_owner":          "Bypass permission checks for operations on IPC objects",
+	"sys_module":         "Load and unload kernel modules",
+	"sys_rawio":          "Perform I/O port operations",
+	"sys_chroot":         "Use chroot()",
+	"sys_ptrace":         "Trace arbitrary processes using ptrace()",
+	"sys_pacct":          "Use acct()",
+	"sys_admin":          "Perform a range of system administration operations",
+	"sys_boot":           "Use reboot() and kexec_load()",
+	"sys_nice":           "Raise process nice value and change the nice value for other processes",
+	"sys_resource":       "Override resource limits",
+	"sys_time":           "Set system clock",
+	"sys_tty_config":     "Perform privileged tty operations",
+	"mknod":              "Create special files using mknod()",
+	"lease":              "Establish leases on files",
+	"audit_write":        "Write records to kernel auditing log",
+	"audit_control":      "Enable and disable kernel auditing",
+	"setfcap":            "Set file capabilities",
+	"mac_override":       "Override mandatory access control",
+	"mac_admin":          "Configure mandatory access control",
+	"syslog":             "Perform privileged syslog() operations",
+	"wake_alarm":         "Trigger system wake-up",
+	"block_suspend":      "Employ features that block system suspend",
+	"audit_read":         "Allow reading the audit log via a multicast netlink socket",
+	"perfmon":            "Access performance monitoring and observability operations",
+	"bpf":                "Use privileged BPF operations",
+	"checkpoint_restore": "Checkpoint and restore processes using CRIU",
+}
+
+// linuxCapabilitiesWithDesc maps every known Linux capability, in both its
+// canonical "CAP_XXX" form and the bare lowercase "xxx" synonym, to a short
+// description, for use with completion.FromListWithDesc. The lowercase
+// synonym lets users complete a capability without typing the "CAP_" prefix
+// or matching its case.
 //
-// Zsh does not appear have a dedicated option, but allows setting matching-rules
-// (see https://superuser.com/a/1092328).
-var allLinuxCapabilities = sync.OnceValue(func() []string {
+// Both forms are always offered, so shells that match case-sensitively show
+// each capability twice. cli.BashCompletionSetup and cli.ZshCompletionSetup
+// (see cli/cobra.go) turn on case-insensitive matching for bash and zsh;
+// they are not part of the scripts that `docker completion bash|zsh`
+// generate.
+var linuxCapabilitiesWithDesc = sync.OnceValue(func() map[string]string {
 	caps := capability.ListKnown()
-	out := make([]string, 0, len(caps)+1)
-	out = append(out, allCaps)
+	out := make(map[string]string, 2*len(caps)+1)
+	out[allCaps] = "Enable all capabilities"
 	for _, c := range caps {
-		out = append(out, "CAP_"+strings.ToUpper(c.String()))
+		desc := linuxCapabilityDescriptions[c.String()]
+		out["CAP_"+strings.ToUpper(c.String())] = desc
+		out[c.String()] = desc
 	}
 	return out
 })
 
-// restartPolicies is a list of all valid restart-policies..
-//
-// TODO(thaJeztah): add descriptions, and enable descriptions for our completion scripts (cobra.CompletionOptions.DisableDescriptions is currently set to "true")
-var restartPolicies = []string{
-	string(container.RestartPolicyDisabled),
-	string(container.RestartPolicyAlways),
-	string(container.RestartPolicyOnFailure),
-	string(container.RestartPolicyUnlessStopped),
+// builtinLogDrivers is the set of logging drivers built into the daemon.
+// It's used as a fallback when the daemon can't be reached to ask for its
+// actual list of registered logging-driver plugins.
+var builtinLogDrivers = []string{
+	"json-file",
+	"local",
+	"journald",
+	"syslog",
+	"gelf",
+	"fluentd",
+	"awslogs",
+	"splunk",
+	"etwlogs",
+	"gcplogs",
+	"none",
+}
+
+// logDriverOptions maps a logging driver to the log-opt keys it supports.
+// Keys that take an enumerable set of values list them; keys with a nil (or
+// empty) value take a freeform value that can't usefully be completed.
+var logDriverOptions = map[string]map[string][]string{
+	"json-file": {
+		"max-size": nil, "max-file": nil, "compress": {"on", "off"},
+		"labels": nil, "env": nil, "env-regex": nil,
+		"mode": {"blocking", "non-blocking"}, "max-buffer-size": nil,
+	},
+	"local": {
+		"max-size": nil, "max-file": nil, "compress": {"on", "off"},
+		"labels": nil, "env": nil, "env-regex": nil,
+		"mode": {"blocking", "non-blocking"}, "max-buffer-size": nil,
+	},
+	"syslog": {
+		"syslog-address": nil, "syslog-facility": nil, "syslog-tls-ca-cert": nil,
+		"syslog-tls-cert": nil, "syslog-tls-key": nil, "syslog-tls-skip-verify": {"true", "false"},
+		"tag": nil, "labels": nil, "env": nil, "env-regex": nil, "syslog-format": nil,
+	},
+	"gelf": {
+		"gelf-address": nil, "gelf-compression-type": {"gzip", "zlib", "none"}, "gelf-compression-level": nil,
+		"tag": nil, "labels": nil, "env": nil, "env-regex": nil,
+	},
+	"fluentd": {
+		"fluentd-address": nil, "fluentd-async": {"true", "false"}, "fluentd-buffer-limit": nil,
+		"fluentd-retry-wait": nil, "fluentd-max-retries": nil, "fluentd-sub-second-precision": {"true", "false"},
+		"tag": nil, "labels": nil, "env": nil, "env-regex": nil,
+	},
+	"awslogs": {
+		"awslogs-region": nil, "awslogs-group": nil, "awslogs-stream": nil,
+		"awslogs-create-group": {"true", "false"}, "awslogs-datetime-format": nil, "awslogs-multiline-pattern": nil,
+		"awslogs-credentials-endpoint": nil, "tag": nil, "mode": {"blocking", "non-blocking"},
+	},
+	"splunk": {
+		"splunk-token": nil, "splunk-url": nil, "splunk-source": nil, "splunk-sourcetype": nil, "splunk-index": nil,
+		"splunk-capath": nil, "splunk-caname": nil, "splunk-insecureskipverify": {"true", "false"},
+		"splunk-format": {"raw", "json", "inline"}, "splunk-verify-connection": {"true", "false"},
+		"splunk-gzip": {"true", "false"}, "splunk-gzip-level": nil,
+		"tag": nil, "labels": nil, "env": nil, "env-regex": nil,
+	},
+	"etwlogs": {},
+	"gcplogs": {"gcp-project": nil, "gcp-log-cmd": {"true", "false"}, "labels": nil, "env": nil, "env-regex": nil},
+	"none":    {},
+}
+
+// restartPolicies maps each valid restart-policy to a short description of
+// its behavior, for use with completion.FromListWithDesc.
+var restartPolicies = map[string]string{
+	string(container.RestartPolicyDisabled):      "Do not automatically restart",
+	string(container.RestartPolicyAlways):        "Always restart regardless of exit status",
+	string(container.RestartPolicyOnFailure):     "Restart on non-zero exit",
+	string(container.RestartPolicyUnlessStopped): "Always restart, but don't start it on daemon startup if stopped manually",
+}
+
+// pullPolicies maps each valid `--pull` value to a short description of its
+// behavior, for use with completion.FromListWithDesc.
+var pullPolicies = map[string]string{
+	PullImageAlways:  "Always pull the image",
+	PullImageMissing: "Pull the image only when it's missing locally",
+	PullImageNever:   "Never pull the image, use the local image only",
+}
+
+// cgroupNamespaceModes maps each valid `--cgroupns` value to a short
+// description, for use with completion.FromListWithDesc.
+var cgroupNamespaceModes = map[string]string{
+	"host":    "Run the container in the daemon's cgroup namespace",
+	"private": "Run the container in its own private cgroup namespace",
 }
 
 // addCompletions adds the completions that `run` and `create`have in common.
 func addCompletions(cmd *cobra.Command, dockerCli command.Cli) {
 	_ = cmd.RegisterFlagCompletionFunc("add-host", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("annotation", completion.NoComplete)
-	_ = cmd.RegisterFlagCompletionFunc("attach", completion.FromList("stderr", "stdin", "stdout"))
+	_ = cmd.RegisterFlagCompletionFunc("attach", completion.FromListWithDesc(map[string]string{
+		"stdin":  "Attach to the container's stdin",
+		"stdout": "Attach to the container's stdout",
+		"stderr": "Attach to the container's stderr",
+	}))
 	_ = cmd.RegisterFlagCompletionFunc("blkio-weight", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("blkio-weight-device", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("cap-add", completeLinuxCapabilityNames)
 	_ = cmd.RegisterFlagCompletionFunc("cap-drop", completeLinuxCapabilityNames)
 	_ = cmd.RegisterFlagCompletionFunc("cgroup-parent", completion.NoComplete)
-	_ = cmd.RegisterFlagCompletionFunc("cgroupns", completion.FromList("host", "private"))
+	_ = cmd.RegisterFlagCompletionFunc("cgroupns", completion.FromListWithDesc(cgroupNamespaceModes))
 	_ = cmd.RegisterFlagCompletionFunc("cpu-period", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("cpu-quota", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("cpu-rt-period", completion.NoComplete)
@@ -87,7 +212,7 @@ func addCompletions(cmd *cobra.Command, dockerCli command.Cli) {
 	_ = cmd.RegisterFlagCompletionFunc("expose", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("env", completion.EnvVarNames)
 	_ = cmd.RegisterFlagCompletionFunc("env-file", completion.FileNames)
-	_ = cmd.RegisterFlagCompletionFunc("gpus", completion.NoComplete)
+	_ = cmd.RegisterFlagCompletionFunc("gpus", completeGpus(dockerCli))
 	_ = cmd.RegisterFlagCompletionFunc("group-add", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("health-cmd", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("health-interval", completion.NoComplete)
@@ -104,22 +229,23 @@ func addCompletions(cmd *cobra.Command, dockerCli command.Cli) {
 	_ = cmd.RegisterFlagCompletionFunc("label", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("link", completeLink(dockerCli))
 	_ = cmd.RegisterFlagCompletionFunc("link-local-ip", completion.NoComplete)
-	_ = cmd.RegisterFlagCompletionFunc("log-driver", completion.NoComplete) // TODO complete drivers
-	_ = cmd.RegisterFlagCompletionFunc("log-opt", completion.NoComplete)    // TODO complete driver options
+	_ = cmd.RegisterFlagCompletionFunc("log-driver", completeLogDrivers(dockerCli))
+	_ = cmd.RegisterFlagCompletionFunc("log-opt", completeLogOpts)
 	_ = cmd.RegisterFlagCompletionFunc("mac-address", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("memory", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("memory-reservation", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("memory-swap", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("memory-swappiness", completion.NoComplete)
-	_ = cmd.RegisterFlagCompletionFunc("mount", completion.NoComplete)
+	_ = cmd.RegisterFlagCompletionFunc("mount", completeMount(dockerCli))
 	_ = cmd.RegisterFlagCompletionFunc("name", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("network", completion.NetworkNames(dockerCli))
 	_ = cmd.RegisterFlagCompletionFunc("network-alias", completion.NoComplete)
 	_ = cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
-	_ = cmd.RegisterFlagCompletionFunc("pull", completion.FromList(PullImageAlways, PullImageMissing, PullImageNever))
+	_ = cmd.RegisterFlagCompletionFunc("pull", completion.FromListWithDesc(pullPolicies))
 	_ = cmd.RegisterFlagCompletionFunc("restart", completeRestartPolicies)
 	_ = cmd.RegisterFlagCompletionFunc("stop-signal", completeSignals)
-	_ = cmd.RegisterFlagCompletionFunc("volumes-from", completion.ContainerNames(dockerCli, true))
+	_ = cmd.RegisterFlagCompletionFunc("volume", completeVolume(dockerCli))
+	_ = cmd.RegisterFlagCompletionFunc("volumes-from", completeVolumesFrom(dockerCli))
 }
 
 // completeIpc implements shell completion for the `--ipc` option of `run` and `create`.
@@ -133,7 +259,14 @@ func completeIpc(cli command.Cli) func(cmd *cobra.Command, args []string, toComp
 			names, _ := completion.ContainerNames(cli, true)(cmd, args, toComplete)
 			return prefixWith("container:", names), cobra.ShellCompDirectiveNoFileComp
 		}
-		return []string{"container:", "host", "none", "private", "shareable"}, cobra.ShellCompDirectiveNoFileComp
+		names, _ := completion.FromListWithDesc(map[string]string{
+			"container:": "Reuse another container's IPC namespace",
+			"host":       "Use the host's IPC namespace",
+			"none":       "Own private IPC namespace, with /dev/shm not mounted",
+			"private":    "Own private IPC namespace",
+			"shareable":  "Own private IPC namespace, which can be shared with other containers",
+		})(cmd, args, toComplete)
+		return names, cobra.ShellCompDirectiveNoFileComp
 	}
 }
 
@@ -154,6 +287,47 @@ func containerNames(dockerCLI completion.APIClientProvider, cmd *cobra.Command,
 	return names
 }
 
+// completeVolumesFrom implements shell completion for the `--volumes-from`
+// option of `run` and `create`. Unlike plain container-name completion, it
+// only offers containers that actually have mounts, since other containers
+// have nothing to copy volumes from.
+func completeVolumesFrom(cli command.Cli) completion.ValidArgsFn {
+	return completion.ContainerNamesFiltered(cli, true, func(ctr container.Summary) bool {
+		return len(ctr.Mounts) > 0
+	})
+}
+
+// ContainerFilters returns a ValidArgsFn for the `--filter` flag of commands
+// that list or filter containers, such as `docker ps`. It completes the
+// known filter keys, and the values of the ones that have a well-defined set
+// or a natural name-based completer.
+//
+// It isn't wired up in addCompletions, since that only covers the flags
+// shared by `run` and `create`, and no command in this package registers
+// it. Registering it on a `--filter` flag looks like this:
+//
+//	_ = cmd.RegisterFlagCompletionFunc("filter", ContainerFilters(dockerCli))
+func ContainerFilters(cli command.Cli) completion.ValidArgsFn {
+	return completion.FilterValues(map[string]completion.ValidArgsFn{
+		"id": completion.ContainerNames(cli, false),
+		"name": completion.ContainerNames(cli, false),
+		"label": completion.NoComplete,
+		"status": completion.FromList(
+			"created", "restarting", "running", "removing", "paused", "exited", "dead",
+		),
+		"ancestor": completion.ImageNames(cli),
+		"volume": completion.VolumeNames(cli),
+		"network": completion.NetworkNames(cli),
+		"health": completion.FromList("starting", "healthy", "unhealthy", "none"),
+		"exited": completion.NoComplete,
+		"before": completion.ContainerNames(cli, true),
+		"since": completion.ContainerNames(cli, true),
+		"is-task": completion.FromList("true", "false"),
+		"publish": completion.NoComplete,
+		"expose": completion.NoComplete,
+	})
+}
+
 // prefixWith prefixes every element in the slice with the given prefix.
 func prefixWith(prefix string, values []string) []string {
 	result := make([]string, len(values))
@@ -172,12 +346,249 @@ func postfixWith(postfix string, values []string) []string {
 	return result
 }
 
+// completeCSVKV splits the CSV value currently being completed into the
+// already-typed fields and the key/value of the field under the cursor, so
+// that composite flags such as `--mount` can be completed one `key=value`
+// pair at a time. done holds the fields that were already terminated by a
+// comma, key and value hold the (possibly empty) parts of the last field
+// around the first `=`, and hasEq reports whether that `=` was found.
+func completeCSVKV(toComplete string) (done []string, key string, value string, hasEq bool) {
+	fields := strings.Split(toComplete, ",")
+	current := fields[len(fields)-1]
+	done = fields[:len(fields)-1]
+	key, value, hasEq = strings.Cut(current, "=")
+	return done, key, value, hasEq
+}
+
+// completeMount implements shell completion for the `--mount` option of `run`
+// and `create`. Completion is composite: depending on what's already been
+// typed in the current comma-separated key/value pair, it offers mount keys,
+// then completes the value for the key being typed.
+func completeMount(cli command.Cli) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		done, key, value, hasEq := completeCSVKV(toComplete)
+		prefix := ""
+		if len(done) > 0 {
+			prefix = strings.Join(done, ",") + ","
+		}
+
+		if !hasEq {
+			return prefixWith(prefix, []string{
+				"type=", "source=", "target=", "readonly", "bind-propagation=",
+				"volume-driver=", "tmpfs-size=", "tmpfs-mode=", "consistency=",
+			}), cobra.ShellCompDirectiveNoSpace
+		}
+
+		switch key {
+		case "type":
+			return prefixWith(prefix+"type=", []string{"bind", "volume", "tmpfs", "cluster", "image"}), cobra.ShellCompDirectiveNoSpace
+		case "source", "src":
+			if mountType(done) == "bind" {
+				names, directive := completion.FileNames(cmd, args, value)
+				return prefixWith(prefix+key+"=", names), directive
+			}
+			names, _ := completion.VolumeNames(cli)(cmd, args, value)
+			return prefixWith(prefix+key+"=", names), cobra.ShellCompDirectiveNoFileComp
+		case "bind-propagation":
+			return prefixWith(prefix+"bind-propagation=", []string{
+				"rprivate", "private", "rshared", "shared", "rslave", "slave",
+			}), cobra.ShellCompDirectiveNoFileComp
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// mountType looks for a `type=...` field among the already-typed, comma
+// separated fields of a `--mount` value, and returns its value, or the empty
+// string if none was set yet.
+func mountType(fields []string) string {
+	for _, field := range fields {
+		if k, v, ok := strings.Cut(field, "="); ok && k == "type" {
+			return v
+		}
+	}
+	return ""
+}
+
+// completeVolume implements shell completion for the `--volume` option of
+// `run` and `create`. A `--volume` value has up to three colon-separated
+// fields: source, destination, and a comma-separated list of options.
+func completeVolume(cli command.Cli) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		fields := strings.Split(toComplete, ":")
+		prefix := ""
+		if len(fields) > 1 {
+			prefix = strings.Join(fields[:len(fields)-1], ":") + ":"
+		}
+		current := fields[len(fields)-1]
+
+		switch len(fields) {
+		case 1:
+			names, _ := completion.VolumeNames(cli)(cmd, args, current)
+			paths, directive := completion.FileNames(cmd, args, current)
+			return append(names, paths...), directive
+		case 2:
+			return completion.FileNames(cmd, args, current)
+		case 3:
+			names, directive := completeVolumeOptions(current)
+			return prefixWith(prefix, names), directive
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// volumeOptions are the comma-separated options accepted in the third field
+// of a `--volume` value.
+var volumeOptions = []string{"ro", "rw", "z", "Z", "rshared", "rslave", "rprivate", "nocopy"}
+
+// completeVolumeOptions completes the comma-separated options field of a
+// `--volume` value, offering the individual options that haven't already
+// been typed rather than a single glued-together candidate.
+func completeVolumeOptions(toComplete string) ([]string, cobra.ShellCompDirective) {
+	done, _, _, _ := completeCSVKV(toComplete)
+	prefix := ""
+	if len(done) > 0 {
+		prefix = strings.Join(done, ",") + ","
+	}
+
+	used := make(map[string]bool, len(done))
+	for _, d := range done {
+		used[d] = true
+	}
+	var remaining []string
+	for _, o := range volumeOptions {
+		if !used[o] {
+			remaining = append(remaining, o)
+		}
+	}
+	return prefixWith(prefix, remaining), cobra.ShellCompDirectiveNoSpace
+}
+
 func completeLinuxCapabilityNames(cmd *cobra.Command, args []string, toComplete string) (names []string, _ cobra.ShellCompDirective) {
-	return completion.FromList(allLinuxCapabilities()...)(cmd, args, toComplete)
+	return completion.FromListWithDesc(linuxCapabilitiesWithDesc())(cmd, args, toComplete)
 }
 
 func completeRestartPolicies(cmd *cobra.Command, args []string, toComplete string) (names []string, _ cobra.ShellCompDirective) {
-	return completion.FromList(restartPolicies...)(cmd, args, toComplete)
+	return completion.FromListWithDesc(restartPolicies)(cmd, args, toComplete)
+}
+
+// completeLogDrivers implements shell completion for the `--log-driver` option
+// of `run` and `create`. It asks the daemon for its registered logging plugins
+// and falls back to builtinLogDrivers if the daemon can't be reached.
+func completeLogDrivers(cli command.Cli) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
+		defer cancel()
+
+		info, err := cli.Client().Info(ctx)
+		if err != nil || len(info.Plugins.Log) == 0 {
+			return completion.FromList(builtinLogDrivers...)(cmd, args, toComplete)
+		}
+		return completion.FromList(info.Plugins.Log...)(cmd, args, toComplete)
+	}
+}
+
+// completeLogOpts implements shell completion for the `--log-opt` option of
+// `run` and `create`. It looks at the `--log-driver` flag already typed on the
+// command line (if any) and offers the option keys supported by that driver.
+func completeLogOpts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	driver, _ := cmd.Flags().GetString("log-driver")
+	if driver == "" {
+		driver = "json-file"
+	}
+	opts, ok := logDriverOptions[driver]
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if key, _, hasEq := strings.Cut(toComplete, "="); hasEq {
+		values := opts[key]
+		if len(values) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return prefixWith(key+"=", values), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k+"=")
+	}
+	sort.Strings(keys)
+	return keys, cobra.ShellCompDirectiveNoSpace
+}
+
+// nvidiaCapabilities is the standard set of NVIDIA Container Toolkit
+// capabilities that can be requested through `--gpus capabilities=...`.
+var nvidiaCapabilities = []string{"compute", "compat32", "graphics", "utility", "video", "display", "ngx"}
+
+// completeGpus implements shell completion for the `--gpus` option of `run`
+// and `create`. It's composite: `device=` is completed with the GPU UUIDs
+// that `nvidia-smi` reports, if the daemon has an nvidia-flavored runtime
+// registered (see gpuDeviceIDs), and `capabilities=` is completed with the
+// standard NVIDIA capability set.
+func completeGpus(cli command.Cli) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case strings.HasPrefix(toComplete, "device="):
+			_, value, _ := strings.Cut(toComplete, "=")
+			last := value
+			prefix := "device="
+			if i := strings.LastIndexByte(value, ','); i != -1 {
+				prefix += value[:i+1]
+				last = value[i+1:]
+			}
+			ids := gpuDeviceIDs(cmd.Context(), cli)
+			names, _ := completion.FromList(ids...)(cmd, []string{}, last)
+			return prefixWith(prefix, names), cobra.ShellCompDirectiveNoSpace
+		case strings.HasPrefix(toComplete, "capabilities="):
+			names, _ := completion.FromList(nvidiaCapabilities...)(cmd, []string{}, strings.TrimPrefix(toComplete, "capabilities="))
+			return prefixWith("capabilities=", names), cobra.ShellCompDirectiveNoFileComp
+		default:
+			return []string{"all", "device=", "count=", "capabilities=", "driver="}, cobra.ShellCompDirectiveNoSpace
+		}
+	}
+}
+
+// gpuDeviceIDs lists GPU device UUIDs by asking nvidia-smi, but only when the
+// daemon has a runtime whose name contains "nvidia" registered. It returns
+// nil when the daemon can't be reached or has no such runtime; no device IDs
+// are read from the daemon's info itself.
+func gpuDeviceIDs(ctx context.Context, cli command.Cli) []string {
+	infoCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	info, err := cli.Client().Info(infoCtx)
+	if err != nil {
+		return nil
+	}
+	for name := range info.Runtimes {
+		if strings.Contains(name, "nvidia") {
+			return nvidiaSMIDeviceIDs(ctx)
+		}
+	}
+	return nil
+}
+
+// nvidiaSMIDeviceIDs shells out to nvidia-smi to list GPU UUIDs. It's silent
+// on failure (no nvidia-smi binary, no GPU, timeout, ...) since completion
+// must never fail loudly.
+func nvidiaSMIDeviceIDs(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=uuid", "--format=csv,noheader").Output()
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			ids = append(ids, line)
+		}
+	}
+	return ids
 }
 
 func completeSignals(cmd *cobra.Command, args []string, toComplete string) (names []string, _ cobra.ShellCompDirective) {
@@ -0,0 +1,17 @@
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLinuxCapabilitiesWithDescOffersBothCases(t *testing.T) {
+	caps := linuxCapabilitiesWithDesc()
+	desc, ok := caps["CAP_CHOWN"]
+	assert.Check(t, ok)
+	assert.Check(t, desc != "")
+	lower, ok := caps["chown"]
+	assert.Check(t, ok)
+	assert.Equal(t, lower, desc)
+}
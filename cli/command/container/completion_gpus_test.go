@@ -0,0 +1,25 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestCompleteGpusDefault(t *testing.T) {
+	fn := completeGpus(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, []string{"all", "device=", "count=", "capabilities=", "driver="})
+}
+
+func TestCompleteGpusCapabilities(t *testing.T) {
+	fn := completeGpus(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "capabilities=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.Assert(t, len(values) == len(nvidiaCapabilities))
+	for _, v := range values {
+		assert.Check(t, v[:len("capabilities=")] == "capabilities=")
+	}
+}
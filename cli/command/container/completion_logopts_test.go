@@ -0,0 +1,58 @@
+package container
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func newLogOptsTestCommand(t *testing.T, driver string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "run"}
+	cmd.Flags().String("log-driver", "", "")
+	if driver != "" {
+		assert.NilError(t, cmd.Flags().Set("log-driver", driver))
+	}
+	return cmd
+}
+
+func TestCompleteLogOptsKeys(t *testing.T) {
+	cmd := newLogOptsTestCommand(t, "syslog")
+	values, directive := completeLogOpts(cmd, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, []string{
+		"env=", "env-regex=", "labels=", "syslog-address=", "syslog-facility=",
+		"syslog-format=", "syslog-tls-ca-cert=", "syslog-tls-cert=", "syslog-tls-key=",
+		"syslog-tls-skip-verify=", "tag=",
+	})
+}
+
+func TestCompleteLogOptsDefaultsToJSONFile(t *testing.T) {
+	cmd := newLogOptsTestCommand(t, "")
+	values, _ := completeLogOpts(cmd, []string{}, "compress=")
+	assert.DeepEqual(t, values, []string{"compress=on", "compress=off"})
+}
+
+func TestCompleteLogOptsSplitsEnumerableValues(t *testing.T) {
+	cmd := newLogOptsTestCommand(t, "splunk")
+	values, directive := completeLogOpts(cmd, []string{}, "splunk-format=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	sort.Strings(values)
+	assert.DeepEqual(t, values, []string{"splunk-format=inline", "splunk-format=json", "splunk-format=raw"})
+}
+
+func TestCompleteLogOptsFreeformValueHasNoCompletion(t *testing.T) {
+	cmd := newLogOptsTestCommand(t, "syslog")
+	values, directive := completeLogOpts(cmd, []string{}, "tag=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.Check(t, len(values) == 0)
+}
+
+func TestCompleteLogOptsUnknownDriver(t *testing.T) {
+	cmd := newLogOptsTestCommand(t, "made-up-driver")
+	values, directive := completeLogOpts(cmd, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.Check(t, len(values) == 0)
+}
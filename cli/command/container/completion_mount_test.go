@@ -0,0 +1,91 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestCompleteCSVKV(t *testing.T) {
+	done, key, value, hasEq := completeCSVKV("type=bind,source=")
+	assert.DeepEqual(t, done, []string{"type=bind"})
+	assert.Equal(t, key, "source")
+	assert.Equal(t, value, "")
+	assert.Check(t, hasEq)
+}
+
+func TestCompleteCSVKVNoValueYet(t *testing.T) {
+	done, key, value, hasEq := completeCSVKV("ty")
+	assert.Check(t, len(done) == 0)
+	assert.Equal(t, key, "ty")
+	assert.Equal(t, value, "")
+	assert.Check(t, !hasEq)
+}
+
+func TestMountType(t *testing.T) {
+	assert.Equal(t, mountType([]string{"source=foo", "type=bind"}), "bind")
+	assert.Equal(t, mountType([]string{"source=foo"}), "")
+}
+
+func TestCompleteMountKeys(t *testing.T) {
+	fn := completeMount(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.Assert(t, len(values) > 0)
+	for _, v := range values {
+		assert.Check(t, v == "readonly" || v[len(v)-1] == '=', "unexpected candidate %q", v)
+	}
+}
+
+func TestCompleteMountType(t *testing.T) {
+	fn := completeMount(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "type=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, []string{"type=bind", "type=volume", "type=tmpfs", "type=cluster", "type=image"})
+}
+
+func TestCompleteMountBindPropagation(t *testing.T) {
+	fn := completeMount(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "type=bind,bind-propagation=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.DeepEqual(t, values, []string{
+		"type=bind,bind-propagation=rprivate",
+		"type=bind,bind-propagation=private",
+		"type=bind,bind-propagation=rshared",
+		"type=bind,bind-propagation=shared",
+		"type=bind,bind-propagation=rslave",
+		"type=bind,bind-propagation=slave",
+	})
+}
+
+func TestCompleteMountUnknownKey(t *testing.T) {
+	fn := completeMount(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "bogus=")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+	assert.Check(t, len(values) == 0)
+}
+
+func TestCompleteVolumeOptions(t *testing.T) {
+	values, directive := completeVolumeOptions("")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	assert.DeepEqual(t, values, volumeOptions)
+}
+
+func TestCompleteVolumeOptionsExcludesAlreadyTyped(t *testing.T) {
+	values, _ := completeVolumeOptions("ro,")
+	for _, v := range values {
+		assert.Check(t, v != "ro,ro", "ro should not be offered again, got %q", v)
+	}
+	assert.Assert(t, len(values) > 0)
+}
+
+func TestCompleteVolumeThirdFieldOffersIndividualOptions(t *testing.T) {
+	fn := completeVolume(nil)
+	values, directive := fn(&cobra.Command{}, []string{}, "myvol:/data:")
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoSpace)
+	for _, v := range values {
+		assert.Check(t, v == "myvol:/data:ro" || len(v) > len("myvol:/data:"), "unexpected candidate %q", v)
+	}
+	assert.Assert(t, len(values) == len(volumeOptions))
+}